@@ -0,0 +1,26 @@
+// Package configuration holds the data made available to templates as the root
+// rendering context.
+package configuration
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Configuration is the data made available to templates as the root context
+type Configuration interface {
+	// Validate checks the configuration for structural problems, returning an
+	// error if it is not safe to render with
+	Validate() error
+}
+
+// Map is a map-backed Configuration implementation, typically sourced from a
+// YAML or JSON configuration file
+type Map map[string]interface{}
+
+// Validate implements Configuration
+func (m Map) Validate() error {
+	if m == nil {
+		return errors.New("unexpected 'nil' configuration")
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package configuration
+
+// Environment describes a named set of layered values files that get deep-merged
+// over one another, in order, and exposed to templates under ".Environment"
+// alongside the base configuration; the values are not merged into the base
+// configuration's own top-level keys
+type Environment struct {
+	// Name is the environment's name, as passed to --environment
+	Name string
+	// Values is the deep-merged result of rendering every one of the
+	// environment's values files, in order
+	Values Map
+}
+
+// Merge deep-merges other on top of m and returns the result as a new Map; keys
+// present in other take precedence, and nested maps are merged recursively rather
+// than replaced wholesale (both Map and plain map[string]interface{} values, as
+// decoded from YAML, count as nested maps)
+func (m Map) Merge(other Map) Map {
+	asMap := func(v interface{}) (Map, bool) {
+		switch nested := v.(type) {
+		case Map:
+			return nested, true
+		case map[string]interface{}:
+			return Map(nested), true
+		}
+		return nil, false
+	}
+	result := make(Map, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	for k, v := range other {
+		if existing, ok := asMap(result[k]); ok {
+			if incoming, ok := asMap(v); ok {
+				result[k] = existing.Merge(incoming)
+				continue
+			}
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// WithEnvironment returns a copy of m with env exposed to templates under the
+// "Environment" key, as ".Environment.Name" and ".Environment.Values"
+func (m Map) WithEnvironment(env Environment) Map {
+	merged := make(Map, len(m)+1)
+	for k, v := range m {
+		merged[k] = v
+	}
+	merged["Environment"] = Map{
+		"Name":   env.Name,
+		"Values": env.Values,
+	}
+	return merged
+}
@@ -0,0 +1,356 @@
+package renderer
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// renderIgnoreFile is the ignore file, discovered at the root of a DirRender
+// input tree, used to exclude paths from rendering. It supports a practical
+// subset of gitignore syntax: blank lines and "#" comments, "!" negation,
+// a trailing "/" for directory-only patterns, "*"/"?" glob wildcards, "**"
+// to match across any number of directories, and bare (no-slash) patterns
+// matching at any depth. Bracket character classes ("[abc]") are not
+// supported.
+const renderIgnoreFile = ".renderignore"
+
+// DirRenderOptions configures a DirRender tree walk
+type DirRenderOptions struct {
+	// Include is a glob pattern, matched against each file's path relative to
+	// the input directory, that a file must match to be rendered; empty
+	// matches everything
+	Include string
+	// Exclude is a list of additional glob patterns to skip, on top of ".git"
+	// and whatever the tree's ".renderignore" excludes
+	Exclude []string
+	// Parallel is the number of files rendered concurrently; defaults to 1
+	Parallel int
+	// FailFast aborts the whole run on the first error instead of aggregating
+	// per-file errors and continuing
+	FailFast bool
+	// DryRun prints the planned input -> output mapping without writing anything
+	DryRun bool
+}
+
+// dirRenderJob is a single file queued for rendering by DirRender
+type dirRenderJob struct {
+	inputPath  string
+	outputPath string
+	mode       os.FileMode
+}
+
+// DirRender walks inputDir, renders every file whose path (relative to
+// inputDir) matches opts.Include and doesn't match opts.Exclude, and writes the
+// results to a mirrored tree rooted at outputDir, preserving file mode bits.
+// It always skips ".git", skips files it detects as binary by content
+// sniffing, and additionally honors a ".renderignore" file discovered at
+// inputDir's root (see renderIgnoreFile for the supported pattern syntax).
+// See also FileRender, Render.
+func (r *Renderer) DirRender(inputDir, outputDir string, opts DirRenderOptions) error {
+	if opts.Parallel < 1 {
+		opts.Parallel = 1
+	}
+
+	ignorePatterns, err := loadRenderIgnore(inputDir)
+	if err != nil {
+		return errors.Wrap(err, "can't load .renderignore")
+	}
+	ignore := compileIgnoreRules(ignorePatterns)
+
+	jobs, err := planDirRender(inputDir, outputDir, opts.Include, opts.Exclude, ignore)
+	if err != nil {
+		return errors.Wrap(err, "can't walk input directory")
+	}
+
+	if opts.DryRun {
+		for _, j := range jobs {
+			logrus.Infof("%s -> %s", j.inputPath, j.outputPath)
+		}
+		return nil
+	}
+
+	return r.renderDirJobs(jobs, opts.Parallel, opts.FailFast)
+}
+
+// planDirRender walks inputDir and returns the ordered set of jobs to render,
+// skipping directories, ".git", binary files, and anything matched by include/exclude/ignore
+func planDirRender(inputDir, outputDir, include string, excludes []string, ignore ignoreRules) ([]dirRenderJob, error) {
+	var jobs []dirRenderJob
+	err := filepath.Walk(inputDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(inputDir, path)
+		if err != nil {
+			return err
+		}
+		if skipPath(rel, include, excludes, ignore) {
+			return nil
+		}
+
+		binary, err := isBinary(path)
+		if err != nil {
+			return err
+		}
+		if binary {
+			logrus.Debugf("Skipping binary file: %s", rel)
+			return nil
+		}
+
+		jobs = append(jobs, dirRenderJob{
+			inputPath:  path,
+			outputPath: filepath.Join(outputDir, rel),
+			mode:       info.Mode(),
+		})
+		return nil
+	})
+	return jobs, err
+}
+
+// renderDirJobs renders jobs through a worker pool bounded to parallel
+// goroutines, aggregating per-file errors unless failFast is set, in which
+// case the first error stops the remaining jobs from being picked up
+func (r *Renderer) renderDirJobs(jobs []dirRenderJob, parallel int, failFast bool) error {
+	jobCh := make(chan dirRenderJob)
+	errCh := make(chan error, len(jobs))
+	abort := make(chan struct{})
+	var abortOnce sync.Once
+
+	var wg sync.WaitGroup
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobCh {
+				if err := r.fileRender(job.inputPath, job.outputPath, job.mode); err != nil {
+					errCh <- errors.Wrapf(err, "rendering '%s'", job.inputPath)
+					if failFast {
+						abortOnce.Do(func() { close(abort) })
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobCh)
+		for _, job := range jobs {
+			select {
+			case jobCh <- job:
+			case <-abort:
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+	close(errCh)
+
+	var messages []string
+	for err := range errCh {
+		messages = append(messages, err.Error())
+	}
+	if len(messages) > 0 {
+		return errors.Errorf("%d file(s) failed to render:\n%s", len(messages), strings.Join(messages, "\n"))
+	}
+	return nil
+}
+
+// skipPath reports whether rel should be excluded from rendering: it doesn't
+// match a non-empty include pattern, it matches one of the plain-glob
+// excludes, or ignore says it's excluded
+func skipPath(rel, include string, excludes []string, ignore ignoreRules) bool {
+	if include != "" {
+		if ok, _ := filepath.Match(include, rel); !ok {
+			return true
+		}
+	}
+	for _, pattern := range excludes {
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, filepath.Base(rel)); ok {
+			return true
+		}
+	}
+	return ignore.excludes(rel)
+}
+
+// loadRenderIgnore reads the ".renderignore" file at the root of dir, if
+// present, returning its patterns (blank lines and "#" comments skipped).
+// See renderIgnoreFile for the supported syntax.
+func loadRenderIgnore(dir string) ([]string, error) {
+	f, err := os.Open(filepath.Join(dir, renderIgnoreFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, scanner.Err()
+}
+
+// ignoreRule is a single compiled ".renderignore" pattern
+type ignoreRule struct {
+	negate  bool
+	dirOnly bool
+	re      *regexp.Regexp
+}
+
+// ignoreRules is a compiled ".renderignore" file; later rules take precedence
+// over earlier ones, mirroring gitignore's "last match wins" semantics
+type ignoreRules []ignoreRule
+
+// compileIgnoreRules compiles each raw ".renderignore" pattern via compileIgnoreRule
+func compileIgnoreRules(patterns []string) ignoreRules {
+	rules := make(ignoreRules, 0, len(patterns))
+	for _, p := range patterns {
+		rules = append(rules, compileIgnoreRule(p))
+	}
+	return rules
+}
+
+// excludes reports whether rel is excluded by rules: the last rule that
+// matches rel decides, so a later "!keep.txt" can re-include something an
+// earlier broader pattern excluded
+func (rules ignoreRules) excludes(rel string) bool {
+	excluded := false
+	for _, ru := range rules {
+		if ru.matches(rel) {
+			excluded = !ru.negate
+		}
+	}
+	return excluded
+}
+
+// matches reports whether rel is matched by ru; for a dirOnly rule (one
+// ending in "/"), rel matches if any of its ancestor directories do
+func (ru ignoreRule) matches(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	if !ru.dirOnly {
+		return ru.re.MatchString(rel)
+	}
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if ru.re.MatchString(strings.Join(parts[:i], "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// compileIgnoreRule compiles a single gitignore-style pattern: a leading "!"
+// negates it, a trailing "/" makes it directory-only, a pattern with no
+// slash matches at any depth (otherwise it's anchored to the ignore file's
+// root), and "**" matches across any number of directories
+func compileIgnoreRule(raw string) ignoreRule {
+	pattern := raw
+
+	negate := false
+	if strings.HasPrefix(pattern, "!") {
+		negate = true
+		pattern = pattern[1:]
+	}
+
+	dirOnly := false
+	if strings.HasSuffix(pattern, "/") {
+		dirOnly = true
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+
+	rootAnchored := strings.HasPrefix(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	if !rootAnchored && !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+
+	return ignoreRule{
+		negate:  negate,
+		dirOnly: dirOnly,
+		re:      regexp.MustCompile("^" + globSegmentsToRegex(pattern) + "$"),
+	}
+}
+
+// globSegmentsToRegex translates a gitignore-style pattern into a regex
+// matching the same thing: each path segment's "*"/"?" become "[^/]*"/"[^/]",
+// and a whole "**" segment becomes ".*", collapsing the adjoining slash so it
+// can also match zero directories (e.g. "**/*.tmp" matches both "x.tmp" and
+// "a/b/x.tmp")
+func globSegmentsToRegex(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	parts := make([]string, len(segments))
+	for i, seg := range segments {
+		if seg == "**" {
+			parts[i] = ".*"
+		} else {
+			parts[i] = globSegmentToRegex(seg)
+		}
+	}
+	joined := strings.Join(parts, "/")
+	joined = strings.ReplaceAll(joined, `.*/`, `(?:.*/)?`)
+	joined = strings.ReplaceAll(joined, `/.*`, `(?:/.*)?`)
+	return joined
+}
+
+// globSegmentToRegex translates a single non-"**" path segment's "*"/"?"
+// glob wildcards into regex, escaping everything else literally
+func globSegmentToRegex(segment string) string {
+	var b strings.Builder
+	for _, ch := range segment {
+		switch ch {
+		case '*':
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	return b.String()
+}
+
+// isBinary sniffs the first 512 bytes of the file at path, treating the
+// presence of a NUL byte as the signal that it's binary, the same heuristic
+// git itself uses
+func isBinary(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, 512)
+	n, err := f.Read(buf)
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	return bytes.IndexByte(buf[:n], 0) != -1, nil
+}
@@ -0,0 +1,95 @@
+package renderer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/reefbarman/render/renderer/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		rel      string
+		include  string
+		excludes []string
+		ignore   []string
+		want     bool
+	}{
+		{"no filters", "a/b.txt", "", nil, nil, false},
+		{"include mismatch", "a/b.txt", "*.yaml", nil, nil, true},
+		{"plain exclude glob", "a/b.txt", "", []string{"a/b.txt"}, nil, true},
+		{"ignore double-star prefix matches nested", "a/x.tmp", "", nil, []string{"**/*.tmp"}, true},
+		{"ignore bare pattern matches at any depth", "a/b/debug.log", "", nil, []string{"debug.log"}, true},
+		{"ignore dir-only excludes descendants", "build/out.go", "", nil, []string{"build/"}, true},
+		{"ignore dir-only doesn't match a file named like the dir", "build", "", nil, []string{"build/"}, false},
+		{"negation re-includes a later match", "build/keep.txt", "", nil, []string{"build/", "!build/keep.txt"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ignore := compileIgnoreRules(tt.ignore)
+			assert.Equal(t, tt.want, skipPath(tt.rel, tt.include, tt.excludes, ignore))
+		})
+	}
+}
+
+func TestLoadRenderIgnore(t *testing.T) {
+	dir := t.TempDir()
+	content := "# comment\n\nbuild/\n**/*.tmp\n!build/keep.txt\n"
+	err := os.WriteFile(filepath.Join(dir, renderIgnoreFile), []byte(content), 0644)
+	assert.NoError(t, err)
+
+	patterns, err := loadRenderIgnore(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"build/", "**/*.tmp", "!build/keep.txt"}, patterns)
+}
+
+func TestLoadRenderIgnoreMissing(t *testing.T) {
+	patterns, err := loadRenderIgnore(t.TempDir())
+	assert.NoError(t, err)
+	assert.Nil(t, patterns)
+}
+
+// TestRenderDirJobsFailFastDoesNotPanic guards against the double-close of
+// 'abort' that used to panic the whole run when two workers both hit an
+// error at once with --fail-fast
+func TestRenderDirJobsFailFastDoesNotPanic(t *testing.T) {
+	r := New(configuration.Map{})
+
+	var jobs []dirRenderJob
+	for i := 0; i < 20; i++ {
+		jobs = append(jobs, dirRenderJob{inputPath: "does-not-exist", outputPath: t.TempDir()})
+	}
+
+	assert.NotPanics(t, func() {
+		err := r.renderDirJobs(jobs, 8, true)
+		assert.Error(t, err)
+	})
+}
+
+// TestDirRenderCreatesNestedOutputDirectories is an end-to-end regression test
+// for DirRender mirroring the output tree: the destination subdirectories
+// don't exist ahead of time, so DirRender must create them itself
+func TestDirRenderCreatesNestedOutputDirectories(t *testing.T) {
+	in := t.TempDir()
+	out := filepath.Join(t.TempDir(), "out")
+
+	assert.NoError(t, os.WriteFile(filepath.Join(in, "top.txt"), []byte("top {{ .Name }}"), 0644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(in, "sub", "dir"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(in, "sub", "dir", "a.txt"), []byte("nested {{ .Name }}"), 0644))
+
+	r := New(configuration.Map{"Name": "world"})
+	err := r.DirRender(in, out, DirRenderOptions{})
+	assert.NoError(t, err)
+
+	top, err := os.ReadFile(filepath.Join(out, "top.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "top world", string(top))
+
+	nested, err := os.ReadFile(filepath.Join(out, "sub", "dir", "a.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "nested world", string(nested))
+}
@@ -0,0 +1,99 @@
+package renderer
+
+import (
+	"bytes"
+	"reflect"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// FuncMap is the engine-agnostic set of template helpers passed to Engine.Parse;
+// its underlying type matches text/template.FuncMap so GoTemplateEngine converts it directly
+type FuncMap map[string]interface{}
+
+// Template is an opaquely-compiled template produced by an Engine
+type Template interface {
+	// Name returns the template's name, as passed to Engine.Parse
+	Name() string
+}
+
+// Engine is the pluggable template backend behind Renderer.Parse/Execute.
+// GoTemplateEngine is the default; HandlebarsEngine is also available
+type Engine interface {
+	Parse(name, raw string, funcs FuncMap) (Template, error)
+	Execute(t Template, data interface{}) (string, error)
+}
+
+// DelimitedEngine is implemented by engines that support custom template
+// delimiters; Renderer.Delim uses this to detect engines that don't
+type DelimitedEngine interface {
+	Engine
+	SetDelims(left, right string)
+}
+
+// GoTemplateEngine is the default Engine, backed by the standard library's text/template
+type GoTemplateEngine struct {
+	LeftDelim  string
+	RightDelim string
+	Options    []string
+}
+
+// NewGoTemplateEngine creates a GoTemplateEngine with the package's default delimiters
+func NewGoTemplateEngine(options ...string) *GoTemplateEngine {
+	return &GoTemplateEngine{
+		LeftDelim:  LeftDelim,
+		RightDelim: RightDelim,
+		Options:    options,
+	}
+}
+
+// SetDelims implements DelimitedEngine
+func (e *GoTemplateEngine) SetDelims(left, right string) {
+	e.LeftDelim = left
+	e.RightDelim = right
+}
+
+// Parse implements Engine
+func (e *GoTemplateEngine) Parse(name, raw string, funcs FuncMap) (Template, error) {
+	t, err := template.New(name).
+		Delims(e.LeftDelim, e.RightDelim).
+		Funcs(template.FuncMap(funcs)).
+		Option(e.Options...).
+		Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &goTemplate{t}, nil
+}
+
+// Execute implements Engine
+func (e *GoTemplateEngine) Execute(t Template, data interface{}) (string, error) {
+	gt, ok := t.(*goTemplate)
+	if !ok {
+		return "", errors.Errorf("unexpected template type for GoTemplateEngine: %T", t)
+	}
+
+	var buffer bytes.Buffer
+	err := gt.t.Execute(&buffer, data)
+	if err != nil {
+		retErr := err
+		logrus.Debugf("(%v): %v", reflect.TypeOf(err), err)
+		if execErr, ok := err.(template.ExecError); ok {
+			retErr = errors.Wrapf(err, "Error evaluating the template named: '%s'", execErr.Name)
+		}
+		return "", retErr
+	}
+	return buffer.String(), nil
+}
+
+// goTemplate adapts *template.Template to the Template interface
+type goTemplate struct {
+	t *template.Template
+}
+
+// Name implements Template
+func (g *goTemplate) Name() string {
+	return g.t.Name()
+}
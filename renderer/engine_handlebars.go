@@ -0,0 +1,105 @@
+package renderer
+
+import (
+	"reflect"
+
+	"github.com/aymerick/raymond"
+	"github.com/pkg/errors"
+)
+
+// handlebarsFallibleHelpers lists the custom ExtraFunctions helpers that can fail.
+// Raymond helpers can't return an error, so these are wrapped to panic on
+// failure instead; raymond.Template.Exec recovers helper panics and reports
+// them as the template's returned error
+var handlebarsFallibleHelpers = map[string]bool{
+	"render":   true,
+	"readFile": true,
+	"gzip":     true,
+	"ungzip":   true,
+}
+
+// HandlebarsEngine is an Engine backed by github.com/aymerick/raymond,
+// selectable via --engine handlebars or by ".hbs"/".handlebars" file extensions.
+// It doesn't support custom delimiters: Handlebars syntax is fixed to "{{ }}".
+type HandlebarsEngine struct{}
+
+// NewHandlebarsEngine creates a HandlebarsEngine
+func NewHandlebarsEngine() *HandlebarsEngine {
+	return &HandlebarsEngine{}
+}
+
+// Parse implements Engine, registering extraFunctions as Handlebars helpers
+func (e *HandlebarsEngine) Parse(name, raw string, extraFunctions FuncMap) (Template, error) {
+	t, err := raymond.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	t.RegisterHelpers(toHandlebarsHelpers(extraFunctions))
+	return &handlebarsTemplate{name: name, t: t}, nil
+}
+
+// Execute implements Engine
+func (e *HandlebarsEngine) Execute(t Template, data interface{}) (string, error) {
+	ht, ok := t.(*handlebarsTemplate)
+	if !ok {
+		return "", errors.Errorf("unexpected template type for HandlebarsEngine: %T", t)
+	}
+
+	out, err := ht.t.Exec(data)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error evaluating the template named: '%s'", ht.name)
+	}
+	return out, nil
+}
+
+// toHandlebarsHelpers adapts extraFunctions for raymond.Template.RegisterHelpers,
+// wrapping the fallible ones per handlebarsFallibleHelpers. Raymond only accepts
+// helpers returning exactly one value, so any other function (e.g. sprig's
+// error-returning ones) is left out of the Handlebars helpers.
+func toHandlebarsHelpers(extraFunctions FuncMap) map[string]interface{} {
+	helpers := make(map[string]interface{}, len(extraFunctions))
+	for name, fn := range extraFunctions {
+		if handlebarsFallibleHelpers[name] {
+			if fallible, ok := fn.(func(string) (string, error)); ok {
+				helpers[name] = func(arg string) string {
+					out, err := fallible(arg)
+					if err != nil {
+						panic(err)
+					}
+					return out
+				}
+				continue
+			}
+		}
+		if fv := reflect.ValueOf(fn); fv.Kind() != reflect.Func || fv.Type().NumOut() != 1 {
+			continue
+		}
+		helpers[name] = fn
+	}
+	return helpers
+}
+
+// handlebarsTemplate adapts *raymond.Template to the Template interface
+type handlebarsTemplate struct {
+	name string
+	t    *raymond.Template
+}
+
+// Name implements Template
+func (h *handlebarsTemplate) Name() string {
+	return h.name
+}
+
+// EngineForExt returns the Engine appropriate for a template file's
+// extension: ".hbs" and ".handlebars" select a fresh HandlebarsEngine,
+// anything else falls back to fallback. fallback is typically the caller's
+// already-configured default engine, so extension sniffing for the common
+// case doesn't lose Delim/option configuration by constructing a bare one.
+func EngineForExt(ext string, fallback Engine) Engine {
+	switch ext {
+	case ".hbs", ".handlebars":
+		return NewHandlebarsEngine()
+	default:
+		return fallback
+	}
+}
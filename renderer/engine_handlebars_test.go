@@ -0,0 +1,16 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngineForExt(t *testing.T) {
+	fallback := NewGoTemplateEngine()
+
+	assert.IsType(t, &HandlebarsEngine{}, EngineForExt(".hbs", fallback))
+	assert.IsType(t, &HandlebarsEngine{}, EngineForExt(".handlebars", fallback))
+	assert.Same(t, fallback, EngineForExt(".txt", fallback))
+	assert.Same(t, fallback, EngineForExt("", fallback))
+}
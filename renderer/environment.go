@@ -0,0 +1,90 @@
+package renderer
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/reefbarman/render/files"
+	"github.com/reefbarman/render/renderer/configuration"
+	"gopkg.in/yaml.v3"
+)
+
+// restrictedFunctions returns the FuncMap used to pre-render environment values
+// files: a curated subset of ExtraFunctions with no filesystem access and no
+// recursive rendering, so that declaring environments is always safe
+func (r *Renderer) restrictedFunctions() FuncMap {
+	restricted := FuncMap{}
+	for name, fn := range r.ExtraFunctions() {
+		switch name {
+		case "render", "readFile", "env", "expandenv", "getHostByName":
+			continue
+		}
+		restricted[name] = fn
+	}
+	return restricted
+}
+
+// UseEnvironment selects the named environment block from the configuration
+// (under "environments"), renders and deep-merges its values files in order, and
+// attaches the result to the configuration so templates can see it under
+// ".Environment". Values files ending in ".gotmpl" are pre-rendered with
+// restrictedFunctions before being parsed as YAML; plain YAML files are used as-is.
+func (r *Renderer) UseEnvironment(name string) error {
+	cfgMap, ok := r.configuration.(configuration.Map)
+	if !ok {
+		return errors.New("environments require a map-based configuration")
+	}
+
+	environments, _ := cfgMap["environments"].(configuration.Map)
+	envRaw, ok := environments[name].(configuration.Map)
+	if !ok {
+		return errors.Errorf("unknown environment: '%s'", name)
+	}
+	valuesFiles, _ := envRaw["values"].([]interface{})
+
+	values := configuration.Map{}
+	for _, f := range valuesFiles {
+		path, ok := f.(string)
+		if !ok {
+			return errors.Errorf("environment '%s' has a non-string values entry", name)
+		}
+		layer, err := r.renderValuesFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "rendering environment '%s' values file '%s'", name, path)
+		}
+		values = values.Merge(layer)
+	}
+
+	r.configuration = cfgMap.WithEnvironment(configuration.Environment{
+		Name:   name,
+		Values: values,
+	})
+	return nil
+}
+
+// renderValuesFile reads a single environment values file, pre-rendering it with
+// restrictedFunctions when it has a ".gotmpl" extension, and parses the result as YAML
+func (r *Renderer) renderValuesFile(path string) (configuration.Map, error) {
+	raw, err := files.ReadInput(path)
+	if err != nil {
+		return nil, err
+	}
+	content := string(raw)
+
+	if strings.HasSuffix(path, ".gotmpl") {
+		t, err := r.Parse(path, content, r.restrictedFunctions())
+		if err != nil {
+			return nil, err
+		}
+		content, err = r.Execute(t)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var values configuration.Map
+	if err := yaml.Unmarshal([]byte(content), &values); err != nil {
+		return nil, errors.Wrapf(err, "parsing values file '%s'", path)
+	}
+	return values, nil
+}
@@ -0,0 +1,94 @@
+package renderer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/reefbarman/render/renderer/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeValuesFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestUseEnvironmentLayersValuesFiles(t *testing.T) {
+	dir := t.TempDir()
+	base := writeValuesFile(t, dir, "base.yaml", "name: base\nport: 5432\n")
+	override := writeValuesFile(t, dir, "override.yaml.gotmpl", "host: {{ \"prod-db\" }}\n")
+
+	cfg := configuration.Map{
+		"environments": configuration.Map{
+			"prod": configuration.Map{
+				"values": []interface{}{base, override},
+			},
+		},
+	}
+	r := New(cfg)
+
+	assert.NoError(t, r.UseEnvironment("prod"))
+
+	merged := r.configuration.(configuration.Map)
+	env := merged["Environment"].(configuration.Map)
+	assert.Equal(t, "prod", env["Name"])
+
+	// both layers' keys survive, with the later (.gotmpl, pre-rendered) layer's
+	// keys alongside the base layer's rather than replacing it wholesale
+	values := env["Values"].(configuration.Map)
+	assert.Equal(t, "base", values["name"])
+	assert.Equal(t, 5432, values["port"])
+	assert.Equal(t, "prod-db", values["host"])
+}
+
+func TestMapMergeDeepMergesNestedMaps(t *testing.T) {
+	base := configuration.Map{
+		"db": configuration.Map{
+			"host": "localhost",
+			"port": 5432,
+		},
+		"name": "base",
+	}
+	override := configuration.Map{
+		"db": configuration.Map{
+			"host": "prod-db",
+		},
+	}
+
+	merged := base.Merge(override)
+
+	assert.Equal(t, "base", merged["name"])
+	db := merged["db"].(configuration.Map)
+	assert.Equal(t, "prod-db", db["host"])
+	assert.Equal(t, 5432, db["port"])
+}
+
+func TestUseEnvironmentUnknownName(t *testing.T) {
+	r := New(configuration.Map{"environments": configuration.Map{}})
+	err := r.UseEnvironment("missing")
+	assert.EqualError(t, err, "unknown environment: 'missing'")
+}
+
+type stubConfiguration struct{}
+
+func (stubConfiguration) Validate() error { return nil }
+
+func TestUseEnvironmentRequiresMapConfiguration(t *testing.T) {
+	r := New(stubConfiguration{})
+	err := r.UseEnvironment("prod")
+	assert.EqualError(t, err, "environments require a map-based configuration")
+}
+
+func TestRestrictedFunctionsDropsSideEffectfulHelpers(t *testing.T) {
+	r := New(configuration.Map{})
+	restricted := r.restrictedFunctions()
+
+	for _, name := range []string{"render", "readFile", "env", "expandenv", "getHostByName"} {
+		_, ok := restricted[name]
+		assert.Falsef(t, ok, "expected %q to be excluded from restrictedFunctions", name)
+	}
+	assert.Contains(t, restricted, "toYaml")
+}
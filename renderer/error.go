@@ -0,0 +1,104 @@
+package renderer
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// snippetContext is how many lines of source are shown on either side of the
+// offending line in a RenderError's snippet
+const snippetContext = 3
+
+// positionPattern extracts line/column coordinates from the error strings
+// text/template produces: "template: name:3:12: executing ..." (from
+// template.ExecError, line and column) or "template: name:3: unexpected ..."
+// (from parse.Error, line only — parse errors, by far the most common ones
+// users hit, never carry a column). The column group is optional; when it's
+// absent, column defaults to 1.
+var positionPattern = regexp.MustCompile(`template:\s*[^:]*:(\d+)(?::(\d+))?:`)
+
+// RenderError is a structured, position-aware error returned by Render when a
+// template fails to parse or execute, carrying enough detail for editor/LSP
+// integration. See also Renderer.JSONErrors, Renderer.FormatError.
+type RenderError struct {
+	TemplateName string `json:"templateName"`
+	Line         int    `json:"line"`
+	Column       int    `json:"column"`
+	Message      string `json:"message"`
+	Snippet      string `json:"snippet,omitempty"`
+	Cause        error  `json:"-"`
+}
+
+// Error implements error
+func (e *RenderError) Error() string {
+	if e.Snippet == "" {
+		return fmt.Sprintf("%s:%d:%d: %s", e.TemplateName, e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s\n%s", e.TemplateName, e.Line, e.Column, e.Message, e.Snippet)
+}
+
+// Unwrap lets errors.Is/errors.As see the underlying parse/execute error
+func (e *RenderError) Unwrap() error {
+	return e.Cause
+}
+
+// ToJSON marshals the error for --json-errors
+func (e *RenderError) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// newRenderError builds a RenderError from cause, extracting a line (and,
+// when present, a column) from Go's "template: name:line[:col]: ..." error
+// format and rendering a snippet of rawTemplate around that position
+func newRenderError(templateName, rawTemplate string, cause error) *RenderError {
+	line, column := 1, 1
+	if m := positionPattern.FindStringSubmatch(cause.Error()); m != nil {
+		line, _ = strconv.Atoi(m[1])
+		if m[2] != "" {
+			column, _ = strconv.Atoi(m[2])
+		}
+	}
+
+	return &RenderError{
+		TemplateName: templateName,
+		Line:         line,
+		Column:       column,
+		Message:      cause.Error(),
+		Snippet:      snippetAround(rawTemplate, line, column),
+		Cause:        cause,
+	}
+}
+
+// snippetAround renders up to snippetContext lines on either side of line in
+// raw, with a caret ("^") under column on the offending line
+func snippetAround(raw string, line, column int) string {
+	lines := strings.Split(raw, "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+
+	start := line - snippetContext
+	if start < 1 {
+		start = 1
+	}
+	end := line + snippetContext
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	var b strings.Builder
+	for i := start; i <= end; i++ {
+		fmt.Fprintf(&b, "%4d | %s\n", i, lines[i-1])
+		if i == line {
+			caret := column - 1
+			if caret < 0 {
+				caret = 0
+			}
+			fmt.Fprintf(&b, "     | %s^\n", strings.Repeat(" ", caret))
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
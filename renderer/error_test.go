@@ -0,0 +1,75 @@
+package renderer
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRenderErrorExtractsPosition(t *testing.T) {
+	raw := "line one\nline two\nline {{ .Missing }}\nline four\n"
+	cause := errors.New(`template: demo:3:8: executing "demo" at <.Missing>: map has no entry for key "Missing"`)
+
+	re := newRenderError("demo", raw, cause)
+
+	assert.Equal(t, "demo", re.TemplateName)
+	assert.Equal(t, 3, re.Line)
+	assert.Equal(t, 8, re.Column)
+	assert.Equal(t, cause.Error(), re.Message)
+	assert.Same(t, cause, re.Unwrap())
+}
+
+func TestNewRenderErrorExtractsLineFromARealParseError(t *testing.T) {
+	raw := "line one\nline two\n{{ .Foo"
+	_, parseErr := template.New("demo").Parse(raw)
+	assert.Error(t, parseErr)
+	// parse.Error's format carries no column, unlike template.ExecError
+	assert.Regexp(t, `^template: demo:\d+: `, parseErr.Error())
+
+	re := newRenderError("demo", raw, parseErr)
+
+	assert.Equal(t, 3, re.Line)
+	assert.Equal(t, 1, re.Column)
+}
+
+func TestNewRenderErrorFallsBackToLineOneOnUnrecognizedFormat(t *testing.T) {
+	cause := errors.New("some unrelated failure")
+	re := newRenderError("demo", "only line", cause)
+
+	assert.Equal(t, 1, re.Line)
+	assert.Equal(t, 1, re.Column)
+}
+
+func TestSnippetAroundMarksTheOffendingColumn(t *testing.T) {
+	raw := "one\ntwo\nthree\nfour\nfive"
+	snippet := snippetAround(raw, 3, 3)
+
+	lines := strings.Split(snippet, "\n")
+	assert.Contains(t, lines[0], "1 | one")
+	assert.Contains(t, lines[2], "3 | three")
+	// the caret line directly follows the offending line and points at column 3
+	assert.Equal(t, "     | "+strings.Repeat(" ", 2)+"^", lines[3])
+}
+
+func TestSnippetAroundOutOfRangeLineReturnsEmpty(t *testing.T) {
+	assert.Equal(t, "", snippetAround("one\ntwo", 99, 1))
+	assert.Equal(t, "", snippetAround("one\ntwo", 0, 1))
+}
+
+func TestRenderErrorErrorIncludesSnippetWhenPresent(t *testing.T) {
+	withSnippet := &RenderError{TemplateName: "demo", Line: 1, Column: 1, Message: "boom", Snippet: "   1 | boom"}
+	assert.Equal(t, "demo:1:1: boom\n   1 | boom", withSnippet.Error())
+
+	withoutSnippet := &RenderError{TemplateName: "demo", Line: 1, Column: 1, Message: "boom"}
+	assert.Equal(t, "demo:1:1: boom", withoutSnippet.Error())
+}
+
+func TestRenderErrorToJSON(t *testing.T) {
+	re := &RenderError{TemplateName: "demo", Line: 2, Column: 5, Message: "boom", Snippet: "snippet"}
+	b, err := re.ToJSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"templateName":"demo","line":2,"column":5,"message":"boom","snippet":"snippet"}`, string(b))
+}
@@ -0,0 +1,137 @@
+package renderer
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/format"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// --format names recognized out of the box, see also defaultFormatters
+const (
+	FormatNone = "none"
+	FormatGo   = "go"
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// Formatter post-processes rendered output before it's written, e.g. to keep
+// generated .go templates gofmt-clean; register custom ones with RegisterFormatter
+type Formatter interface {
+	Format(output string) (string, error)
+}
+
+// FormatterFunc adapts a plain function to the Formatter interface
+type FormatterFunc func(output string) (string, error)
+
+// Format implements Formatter
+func (f FormatterFunc) Format(output string) (string, error) {
+	return f(output)
+}
+
+// goFormatter runs output through go/format.Source, the same normalization gofmt applies
+var goFormatter = FormatterFunc(func(output string) (string, error) {
+	formatted, err := format.Source([]byte(output))
+	if err != nil {
+		return "", errors.Wrap(err, "can't gofmt output")
+	}
+	return string(formatted), nil
+})
+
+// jsonFormatter re-marshals output through encoding/json, indented
+var jsonFormatter = FormatterFunc(func(output string) (string, error) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(output), &data); err != nil {
+		return "", errors.Wrap(err, "can't parse output as JSON")
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return "", errors.Wrap(err, "can't format output as JSON")
+	}
+	return buf.String(), nil
+})
+
+// yamlFormatter round-trips output through gopkg.in/yaml.v3 to normalize it
+var yamlFormatter = FormatterFunc(func(output string) (string, error) {
+	var data interface{}
+	if err := yaml.Unmarshal([]byte(output), &data); err != nil {
+		return "", errors.Wrap(err, "can't parse output as YAML")
+	}
+
+	formatted, err := yaml.Marshal(data)
+	if err != nil {
+		return "", errors.Wrap(err, "can't format output as YAML")
+	}
+	return string(formatted), nil
+})
+
+// defaultFormatters maps both --format names and auto-detected file
+// extensions to their Formatter
+func defaultFormatters() map[string]Formatter {
+	return map[string]Formatter{
+		FormatGo:   goFormatter,
+		FormatJSON: jsonFormatter,
+		FormatYAML: yamlFormatter,
+		".go":      goFormatter,
+		".json":    jsonFormatter,
+		".yaml":    yamlFormatter,
+		".yml":     yamlFormatter,
+	}
+}
+
+// RegisterFormatter registers a Formatter under name, which may be a --format
+// value (e.g. "terraform") or a file extension (e.g. ".tf"), so auto-detection
+// and explicit --format both pick it up. Like the rest of the Renderer's
+// builder methods, it's meant to be called while configuring the Renderer,
+// before any concurrent rendering (e.g. DirRender) starts.
+func (r *Renderer) RegisterFormatter(name string, f Formatter) *Renderer {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.formatters == nil {
+		r.formatters = defaultFormatters()
+	}
+	r.formatters[name] = f
+	return r
+}
+
+// WithFormat sets the --format name used by FileRender/DirRender, overriding
+// auto-detection from the output file's extension; pass "" to auto-detect, or
+// FormatNone to disable formatting entirely
+func (r *Renderer) WithFormat(name string) *Renderer {
+	r.formatName = name
+	return r
+}
+
+// formatNameFor resolves the --format name to use for outputPath: the
+// explicit WithFormat value if set, otherwise the output file's extension
+func (r *Renderer) formatNameFor(outputPath string) string {
+	if r.formatName != "" {
+		return r.formatName
+	}
+	return filepath.Ext(outputPath)
+}
+
+// Format runs output through the Formatter registered under name; an empty
+// name, FormatNone, or a name with no registered Formatter passes output through unchanged.
+// Safe to call concurrently, e.g. from DirRender's worker pool.
+func (r *Renderer) Format(name, output string) (string, error) {
+	if name == "" || name == FormatNone {
+		return output, nil
+	}
+	r.mu.Lock()
+	if r.formatters == nil {
+		r.formatters = defaultFormatters()
+	}
+	f, ok := r.formatters[name]
+	r.mu.Unlock()
+	if !ok {
+		return output, nil
+	}
+	return f.Format(output)
+}
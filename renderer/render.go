@@ -1,16 +1,16 @@
 package renderer
 
 import (
-	"bytes"
-	"reflect"
+	"os"
+	"path/filepath"
 	"strings"
-	"text/template"
+	"sync"
 
 	"github.com/Masterminds/sprig"
-	"github.com/sirupsen/logrus"
+	"github.com/pkg/errors"
 	"github.com/reefbarman/render/files"
 	"github.com/reefbarman/render/renderer/configuration"
-	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
 const (
@@ -30,6 +30,31 @@ type Renderer struct {
 	options       []string
 	leftDelim     string
 	rightDelim    string
+	engine        Engine
+	// engineOverridden is set by WithEngine; until then, render picks the
+	// engine per template by file-extension sniffing, see engineFor
+	engineOverridden bool
+	sandboxed        bool
+	maxDepth         int
+	jsonErrors       bool
+	formatName       string
+	formatters       map[string]Formatter
+
+	// mu guards the fields below, which are mutated while rendering; DirRender
+	// runs FileRender concurrently across a worker pool against this same
+	// Renderer, so anything touched inside a render must be synchronized
+	mu           sync.Mutex
+	dependencies map[string]bool
+}
+
+// renderState threads the in-template `render` recursion depth through a
+// single top-level Render call and any nested renders it triggers. It's
+// created fresh per top-level call (Render, SimpleRender, ExtraFunctions)
+// rather than stored on the long-lived *Renderer: DirRender runs many
+// independent top-level Render calls concurrently against the same
+// Renderer, and they must never share one call chain's recursion count.
+type renderState struct {
+	depth int
 }
 
 // New creates a new renderer with the specified configuration and zero or more options
@@ -39,26 +64,101 @@ func New(configuration configuration.Configuration, opts ...string) *Renderer {
 		options:       opts,
 		leftDelim:     LeftDelim,
 		rightDelim:    RightDelim,
+		engine:        NewGoTemplateEngine(opts...),
+		formatters:    defaultFormatters(),
 	}
 }
 
-// Delim mutates Renderer with new left and right delimiters
+// Delim mutates Renderer with new left and right delimiters; engines that
+// don't support custom delimiters (e.g. HandlebarsEngine) ignore this with a
+// warning rather than an error
 func (r *Renderer) Delim(left, right string) *Renderer {
 	r.leftDelim = left
 	r.rightDelim = right
+	if de, ok := r.engine.(DelimitedEngine); ok {
+		de.SetDelims(left, right)
+	} else {
+		logrus.Warnf("engine %T doesn't support custom delimiters, ignoring Delim(%q, %q)", r.engine, left, right)
+	}
+	return r
+}
+
+// JSONErrors toggles --json-errors: when enabled, FormatError emits a
+// *RenderError as JSON instead of the human-readable form, for editor/LSP integration
+func (r *Renderer) JSONErrors(enabled bool) *Renderer {
+	r.jsonErrors = enabled
 	return r
 }
 
+// FormatError renders err for display: as JSON when JSONErrors is enabled and
+// err is a *RenderError, otherwise via err.Error()
+func (r *Renderer) FormatError(err error) string {
+	if r.jsonErrors {
+		if re, ok := err.(*RenderError); ok {
+			if b, jerr := re.ToJSON(); jerr == nil {
+				return string(b)
+			}
+		}
+	}
+	return err.Error()
+}
+
+// WithEngine swaps the Renderer's template backend, e.g. to a HandlebarsEngine
+// selected via --engine handlebars or by EngineForExt file-extension sniffing
+func (r *Renderer) WithEngine(engine Engine) *Renderer {
+	r.engine = engine
+	r.engineOverridden = true
+	if de, ok := engine.(DelimitedEngine); ok {
+		de.SetDelims(r.leftDelim, r.rightDelim)
+	}
+	return r
+}
+
+// engineFor returns the Engine to render templateName with: the explicit
+// engine from WithEngine if one was set, otherwise EngineForExt sniffs it
+// from templateName's extension (e.g. a ".hbs" file renders with
+// HandlebarsEngine even without an explicit --engine flag), falling back to
+// the configured default engine for anything else
+func (r *Renderer) engineFor(templateName string) Engine {
+	if r.engineOverridden {
+		return r.engine
+	}
+	return EngineForExt(filepath.Ext(templateName), r.engine)
+}
+
 // SimpleRender is a simple rendering function, also used as a custom template function
-// to allow in-template recursive rendering, see also Render, RenderWith
+// to allow in-template recursive rendering, see also Render, RenderWith. Recursion is
+// bounded by maxRenderDepth so a runaway `render` loop fails instead of hanging.
 func (r *Renderer) SimpleRender(rawTemplate string) (string, error) {
-	return r.Render("nameless", rawTemplate)
+	return r.scopedRender(&renderState{})(rawTemplate)
+}
+
+// scopedRender returns the `render` template function bound to state, so that
+// every nested `render` call triggered by one top-level Render shares the
+// same recursion counter, without that counter living on the Renderer itself
+func (r *Renderer) scopedRender(state *renderState) func(string) (string, error) {
+	return func(rawTemplate string) (string, error) {
+		state.depth++
+		defer func() { state.depth-- }()
+		if state.depth > r.maxRenderDepth() {
+			return "", errors.Errorf("render recursion exceeded max depth of %d", r.maxRenderDepth())
+		}
+		return r.render("nameless", rawTemplate, state)
+	}
 }
 
-// TODO DirRender
+// defaultFileMode is the permission used for rendered files whose mode isn't
+// otherwise specified, e.g. by FileRender
+const defaultFileMode = 0644
 
-// FileRender is used to render files by path, see also Render
+// FileRender is used to render files by path, see also Render, DirRender
 func (r *Renderer) FileRender(inputPath, outputPath string) error {
+	return r.fileRender(inputPath, outputPath, defaultFileMode)
+}
+
+// fileRender is the shared implementation behind FileRender and DirRender,
+// writing the result with the given file mode
+func (r *Renderer) fileRender(inputPath, outputPath string, mode os.FileMode) error {
 	input, err := files.ReadInput(inputPath)
 	if err != nil {
 		logrus.Debugf("Can't open the template: %v", err)
@@ -77,7 +177,20 @@ func (r *Renderer) FileRender(inputPath, outputPath string) error {
 		return err
 	}
 
-	err = files.WriteOutput(outputPath, []byte(result), 0644)
+	result, err = r.Format(r.formatNameFor(outputPath), result)
+	if err != nil {
+		logrus.Debugf("Can't format the rendered output: %v", err)
+		return err
+	}
+
+	if dir := filepath.Dir(outputPath); dir != "." && dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			logrus.Debugf("Can't create output directory: %v", err)
+			return err
+		}
+	}
+
+	err = files.WriteOutput(outputPath, []byte(result), mode)
 	if err != nil {
 		logrus.Debugf("Can't save the rendered: %v", err)
 		return err
@@ -86,22 +199,33 @@ func (r *Renderer) FileRender(inputPath, outputPath string) error {
 	return nil
 }
 
-// Render is the main rendering function, see also SimpleRender, Configuration and ExtraFunctions
+// Render is the main rendering function, see also SimpleRender, Configuration and ExtraFunctions.
+// Parse/Execute failures are returned as a *RenderError carrying the offending
+// position and a source snippet, see also JSONErrors.
 func (r *Renderer) Render(templateName, rawTemplate string) (string, error) {
+	return r.render(templateName, rawTemplate, &renderState{})
+}
+
+// render is Render's implementation, threading state through to the
+// `render` template function so recursive calls share one recursion counter,
+// and picking the engine per templateName via engineFor
+func (r *Renderer) render(templateName, rawTemplate string, state *renderState) (string, error) {
 	err := r.Validate()
 	if err != nil {
 		logrus.Errorf("Invalid state; %v", err)
 		return "", err
 	}
-	t, err := r.Parse(templateName, rawTemplate, r.ExtraFunctions())
+
+	engine := r.engineFor(templateName)
+	t, err := engine.Parse(templateName, rawTemplate, r.extraFunctions(state))
 	if err != nil {
 		logrus.Errorf("Can't parse the template; %v", err)
-		return "", err
+		return "", newRenderError(templateName, rawTemplate, err)
 	}
-	out, err := r.Execute(t)
+	out, err := engine.Execute(t, r.configuration)
 	if err != nil {
 		logrus.Errorf("Can't execute the template; %v", err)
-		return "", err
+		return "", newRenderError(templateName, rawTemplate, err)
 	}
 	return out, nil
 }
@@ -136,29 +260,14 @@ func (r *Renderer) Validate() error {
 	return nil
 }
 
-// Parse is a basic template parsing function
-func (r *Renderer) Parse(templateName, rawTemplate string, extraFunctions template.FuncMap) (*template.Template, error) {
-	return template.New(templateName).
-		Delims(r.leftDelim, r.rightDelim).
-		Funcs(extraFunctions).
-		Option(r.options...).
-		Parse(rawTemplate)
+// Parse is a basic template parsing function, delegating to the configured Engine
+func (r *Renderer) Parse(templateName, rawTemplate string, extraFunctions FuncMap) (Template, error) {
+	return r.engine.Parse(templateName, rawTemplate, extraFunctions)
 }
 
-// Execute is a basic template execution function
-func (r *Renderer) Execute(t *template.Template) (string, error) {
-	var buffer bytes.Buffer
-	err := t.Execute(&buffer, r.configuration)
-	if err != nil {
-		retErr := err
-		logrus.Debugf("(%v): %v", reflect.TypeOf(err), err)
-		if e, ok := err.(template.ExecError); ok {
-			retErr = errors.Wrapf(err,
-				"Error evaluating the template named: '%s'", e.Name)
-		}
-		return "", retErr
-	}
-	return buffer.String(), nil
+// Execute is a basic template execution function, delegating to the configured Engine
+func (r *Renderer) Execute(t Template) (string, error) {
+	return r.engine.Execute(t, r.configuration)
 }
 
 /*
@@ -167,18 +276,28 @@ it adds sprig functions and custom functions:
 
   - render - calls the render from inside of the template, making the renderer recursive
   - readFile - reads a file from a given path, relative paths are translated to absolute
-          paths, based on root function
+    paths, based on root function
   - root - the root path for rendering, used relative to absolute path translation
-          in any file based operations
+    in any file based operations
   - toYaml - provides a configuration data structure fragment as a YAML format
   - gzip - use gzip compression inside the templates, for best results use with b64enc
   - ungzip - use gzip extraction inside the templates, for best results use with b64dec
-
 */
-func (r *Renderer) ExtraFunctions() template.FuncMap {
-	extraFunctions := sprig.TxtFuncMap()
-	extraFunctions["render"] = r.SimpleRender
-	extraFunctions["readFile"] = r.ReadFile
+func (r *Renderer) ExtraFunctions() FuncMap {
+	return r.extraFunctions(&renderState{})
+}
+
+// extraFunctions is ExtraFunctions' implementation, binding the `render`
+// helper to state so a top-level Render's recursive calls share one
+// recursion counter instead of a Renderer-wide one
+func (r *Renderer) extraFunctions(state *renderState) FuncMap {
+	if r.sandboxed {
+		return r.sandboxFunctions(state)
+	}
+
+	extraFunctions := FuncMap(sprig.TxtFuncMap())
+	extraFunctions["render"] = r.scopedRender(state)
+	extraFunctions["readFile"] = r.trackedReadFile
 	extraFunctions["toYaml"] = ToYaml
 	extraFunctions["ungzip"] = Ungzip
 	extraFunctions["gzip"] = Gzip
@@ -0,0 +1,56 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/reefbarman/render/renderer/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestScopedRenderDepthIsIsolatedPerState guards against renderDepth living on
+// the shared *Renderer: DirRender runs many independent SimpleRender/Render
+// call chains concurrently against one Renderer, so one chain already deep in
+// recursion must never cause a sibling chain to spuriously hit maxRenderDepth.
+func TestScopedRenderDepthIsIsolatedPerState(t *testing.T) {
+	r := New(configuration.Map{}).MaxRenderDepth(1)
+
+	// stateA simulates a call chain that's already mid-recursion, sitting
+	// right at the configured max depth
+	stateA := &renderState{depth: 1}
+
+	// stateB is an unrelated, freshly-started top-level render
+	_, err := r.scopedRender(&renderState{})("plain template, no actions")
+
+	assert.NoError(t, err, "an independent call chain must not inherit another chain's recursion depth")
+	assert.Equal(t, 1, stateA.depth, "an unrelated render must not mutate another call chain's state")
+}
+
+// TestRenderSniffsEngineByExtensionWhenNotOverridden covers the other half of
+// EngineForExt's promise: Render itself must pick an engine per templateName
+// when the caller hasn't pinned one with WithEngine.
+func TestRenderSniffsEngineByExtensionWhenNotOverridden(t *testing.T) {
+	r := New(configuration.Map{"name": "world"})
+
+	out, err := r.Render("demo.hbs", "Hello {{name}}")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello world", out)
+}
+
+func TestRenderDefaultsToConfiguredEngineForOtherExtensions(t *testing.T) {
+	r := New(configuration.Map{"Name": "world"})
+
+	out, err := r.Render("demo.txt", "Hello {{ .Name }}")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello world", out)
+}
+
+func TestWithEngineOverridesExtensionSniffing(t *testing.T) {
+	r := New(configuration.Map{"Name": "world"}).WithEngine(NewGoTemplateEngine())
+
+	out, err := r.Render("demo.hbs", "Hello {{ .Name }}")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello world", out)
+}
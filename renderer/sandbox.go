@@ -0,0 +1,52 @@
+package renderer
+
+import (
+	"github.com/Masterminds/sprig"
+)
+
+// defaultMaxRenderDepth bounds in-template recursive `render` calls
+const defaultMaxRenderDepth = 10
+
+// sprigImpureFunctions are sprig helpers with side effects or host access,
+// excluded from the sandboxed FuncMap
+var sprigImpureFunctions = []string{"env", "expandenv", "getHostByName"}
+
+// Sandbox toggles sandbox mode: when enabled, ExtraFunctions returns a curated
+// allowlist (sprig's pure functions only, no readFile, no future side-effectful
+// helpers like decryptAws or exec) so untrusted templates can be rendered
+// safely, e.g. in a CI pipeline that accepts user-submitted config. See also
+// MaxRenderDepth.
+func (r *Renderer) Sandbox(enabled bool) *Renderer {
+	r.sandboxed = enabled
+	return r
+}
+
+// MaxRenderDepth overrides the recursion depth at which in-template `render`
+// calls fail; defaults to defaultMaxRenderDepth
+func (r *Renderer) MaxRenderDepth(depth int) *Renderer {
+	r.maxDepth = depth
+	return r
+}
+
+func (r *Renderer) maxRenderDepth() int {
+	if r.maxDepth <= 0 {
+		return defaultMaxRenderDepth
+	}
+	return r.maxDepth
+}
+
+// sandboxFunctions returns the curated FuncMap used when Sandbox is enabled:
+// sprig's pure functions plus toYaml/gzip/ungzip and a depth-bounded `render`,
+// with readFile and any side-effectful helper left out entirely. render is
+// bound to state so recursion depth is tracked per call chain, see renderState.
+func (r *Renderer) sandboxFunctions(state *renderState) FuncMap {
+	extraFunctions := FuncMap(sprig.TxtFuncMap())
+	for _, name := range sprigImpureFunctions {
+		delete(extraFunctions, name)
+	}
+	extraFunctions["render"] = r.scopedRender(state)
+	extraFunctions["toYaml"] = ToYaml
+	extraFunctions["ungzip"] = Ungzip
+	extraFunctions["gzip"] = Gzip
+	return extraFunctions
+}
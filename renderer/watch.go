@@ -0,0 +1,109 @@
+package renderer
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+)
+
+// watchDebounce coalesces bursts of filesystem events (e.g. an editor's
+// save-then-rewrite) into a single re-render
+const watchDebounce = 100 * time.Millisecond
+
+// trackedReadFile wraps ReadFile, recording the resolved path into the current
+// render's dependency set before delegating, so Watch can subscribe to every
+// file a template pulls in via readFile
+func (r *Renderer) trackedReadFile(path string) (string, error) {
+	if abs, err := filepath.Abs(path); err == nil {
+		r.mu.Lock()
+		if r.dependencies != nil {
+			r.dependencies[abs] = true
+		}
+		r.mu.Unlock()
+	}
+	return r.ReadFile(path)
+}
+
+// Watch renders inputPath to outputPath once, then re-renders whenever
+// inputPath or any file pulled in through readFile changes, until the returned
+// stop function is called. A failed re-render is logged and leaves the last
+// good output in place rather than exiting.
+func (r *Renderer) Watch(inputPath, outputPath string) (stop func(), err error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	render := func() error {
+		r.mu.Lock()
+		r.dependencies = map[string]bool{}
+		r.mu.Unlock()
+		if err := r.FileRender(inputPath, outputPath); err != nil {
+			return err
+		}
+		return r.syncWatches(watcher, inputPath)
+	}
+
+	if err := render(); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go r.watchLoop(watcher, render, done)
+
+	return func() {
+		close(done)
+		watcher.Close()
+	}, nil
+}
+
+// syncWatches adds inputPath and every path recorded in r.dependencies to watcher
+func (r *Renderer) syncWatches(watcher *fsnotify.Watcher, inputPath string) error {
+	paths := map[string]bool{inputPath: true}
+	r.mu.Lock()
+	for dep := range r.dependencies {
+		paths[dep] = true
+	}
+	r.mu.Unlock()
+	for path := range paths {
+		if err := watcher.Add(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watchLoop debounces fsnotify events by watchDebounce and triggers render,
+// logging (but never exiting on) render errors
+func (r *Renderer) watchLoop(watcher *fsnotify.Watcher, render func() error, done <-chan struct{}) {
+	var timer *time.Timer
+	for {
+		select {
+		case <-done:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.AfterFunc(watchDebounce, func() {
+				if err := render(); err != nil {
+					logrus.Errorf("watch: re-render failed, keeping last good output: %v", err)
+				}
+			})
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			logrus.Errorf("watch: %v", err)
+		}
+	}
+}